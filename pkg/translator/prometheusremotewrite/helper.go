@@ -0,0 +1,299 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"hash/fnv"
+	"sort"
+	"unicode"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	keyStr      = "key"
+	jobStr      = "job"
+	instanceStr = "instance"
+	traceIDKey  = "trace_id"
+	spanIDKey   = "span_id"
+	nameLabel   = "__name__"
+)
+
+// bucketBoundsData stores the series signature and bucket upper bound so a sorted slice of these can be used to
+// find the right bucket for an exemplar.
+type bucketBoundsData struct {
+	sig   uint64
+	bound float64
+}
+
+// byBucketBoundsData enables the usage of sort.Sort() with a slice of bucket bounds.
+type byBucketBoundsData []bucketBoundsData
+
+func (m byBucketBoundsData) Len() int           { return len(m) }
+func (m byBucketBoundsData) Less(i, j int) bool { return m[i].bound < m[j].bound }
+func (m byBucketBoundsData) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// byLabelName enables the usage of sort.Sort() with a slice of labels.
+type byLabelName []prompb.Label
+
+func (a byLabelName) Len() int           { return len(a) }
+func (a byLabelName) Less(i, j int) bool { return a[i].Name < a[j].Name }
+func (a byLabelName) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// validateMetrics returns a bool representing whether the metric has a valid type and temporality combination and
+// at least one data point.
+func validateMetrics(metric pdata.Metric) bool {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeIntGauge:
+		return metric.IntGauge().DataPoints().Len() != 0
+	case pdata.MetricDataTypeDoubleGauge:
+		return metric.DoubleGauge().DataPoints().Len() != 0
+	case pdata.MetricDataTypeIntSum:
+		return metric.IntSum().DataPoints().Len() != 0
+	case pdata.MetricDataTypeDoubleSum:
+		return metric.DoubleSum().DataPoints().Len() != 0 &&
+			metric.DoubleSum().AggregationTemporality() == pdata.MetricAggregationTemporalityCumulative
+	case pdata.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len() != 0 &&
+			metric.Histogram().AggregationTemporality() == pdata.MetricAggregationTemporalityCumulative
+	case pdata.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len() != 0
+	case pdata.MetricDataTypeExponentialHistogram:
+		// Unlike the classic Histogram type, both temporalities are valid: delta points are translated with a
+		// GAUGE reset hint rather than being rejected outright.
+		return metric.ExponentialHistogram().DataPoints().Len() != 0
+	}
+	return false
+}
+
+// timeSeriesSignature returns a 64-bit hash identifying the time series made up of the metric's data type and the
+// given label set. The label set is sorted by name first so that two label sets differing only in order produce
+// the same signature. Hashing (rather than string concatenation) keeps the signature cheap to compute and small to
+// store for workloads with many series and long label values.
+func timeSeriesSignature(metric pdata.Metric, labels *[]prompb.Label) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(metric.DataType().String()))
+
+	sorted := make([]prompb.Label, len(*labels))
+	copy(sorted, *labels)
+	sort.Sort(byLabelName(sorted))
+
+	for _, lb := range sorted {
+		_, _ = h.Write([]byte(lb.Name))
+		_, _ = h.Write([]byte(lb.Value))
+	}
+
+	return h.Sum64()
+}
+
+// labelsMatch reports whether a and b describe the same label set, independent of order. It is used to resolve
+// hash collisions in the signature computed by timeSeriesSignature.
+func labelsMatch(a, b []prompb.Label) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	av := make(map[string]string, len(a))
+	for _, l := range a {
+		av[l.Name] = l.Value
+	}
+	for _, l := range b {
+		if v, ok := av[l.Name]; !ok || v != l.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// addSample finds a TimeSeries that corresponds to the hash signature of metric and labels in tsMap, and adds
+// sample to it. If none exists, a new TimeSeries is created and inserted into tsMap. tsMap, sample and labels
+// must be non-nil.
+//
+// On a genuine hash collision (a different label set hashing to the same signature), the colliding series is
+// chained into the next free slot by linearly probing forward from sig, so it gets its own TimeSeries instead of
+// being merged into, or dropped in favor of, the series already occupying sig.
+func addSample(tsMap map[uint64]*prompb.TimeSeries, sample *prompb.Sample, labels []prompb.Label, metric pdata.Metric) {
+	if sample == nil || labels == nil || tsMap == nil {
+		return
+	}
+
+	for sig := timeSeriesSignature(metric, &labels); ; sig++ {
+		ts, ok := tsMap[sig]
+		if !ok {
+			tsMap[sig] = &prompb.TimeSeries{
+				Labels:  labels,
+				Samples: []prompb.Sample{*sample},
+			}
+			return
+		}
+		if labelsMatch(ts.Labels, labels) {
+			ts.Samples = append(ts.Samples, *sample)
+			return
+		}
+	}
+}
+
+// addExemplars finds the respective TimeSeries based on the bucket bounds signature and adds exemplars to it.
+func addExemplars(tsMap map[uint64]*prompb.TimeSeries, exemplars []prompb.Exemplar, bucketBounds []bucketBoundsData) {
+	if len(bucketBounds) == 0 {
+		return
+	}
+
+	sort.Sort(byBucketBoundsData(bucketBounds))
+
+	for _, exemplar := range exemplars {
+		addSingleExemplar(tsMap, exemplar, bucketBounds)
+	}
+}
+
+func addSingleExemplar(tsMap map[uint64]*prompb.TimeSeries, exemplar prompb.Exemplar, bucketBounds []bucketBoundsData) {
+	for _, bound := range bucketBounds {
+		ts, ok := tsMap[bound.sig]
+		if !ok {
+			continue
+		}
+		if exemplar.Value <= bound.bound {
+			ts.Exemplars = append(ts.Exemplars, exemplar)
+			return
+		}
+	}
+}
+
+// getPromExemplars returns a slice of prompb.Exemplar built from the exemplars attached to hp, or nil if there are
+// none.
+func getPromExemplars(hp pdata.HistogramDataPoint) []prompb.Exemplar {
+	return getPromExemplarsFromSlice(hp.Exemplars())
+}
+
+// getPromExemplarsFromExponentialHistogram returns a slice of prompb.Exemplar built from the exemplars attached to
+// an exponential histogram data point, or nil if there are none.
+func getPromExemplarsFromExponentialHistogram(hp pdata.ExponentialHistogramDataPoint) []prompb.Exemplar {
+	return getPromExemplarsFromSlice(hp.Exemplars())
+}
+
+func getPromExemplarsFromSlice(otelExemplars pdata.ExemplarSlice) []prompb.Exemplar {
+	var exemplars []prompb.Exemplar
+
+	for i := 0; i < otelExemplars.Len(); i++ {
+		exemplar := otelExemplars.At(i)
+
+		promExemplar := prompb.Exemplar{
+			Value:     exemplar.DoubleVal(),
+			Timestamp: timestamp.FromTime(exemplar.Timestamp().AsTime()),
+		}
+		if traceID := exemplar.TraceID(); !traceID.IsEmpty() {
+			promExemplar.Labels = append(promExemplar.Labels, prompb.Label{
+				Name:  traceIDKey,
+				Value: traceID.HexString(),
+			})
+		}
+		if spanID := exemplar.SpanID(); !spanID.IsEmpty() {
+			promExemplar.Labels = append(promExemplar.Labels, prompb.Label{
+				Name:  spanIDKey,
+				Value: spanID.HexString(),
+			})
+		}
+		exemplar.FilteredAttributes().Range(func(key string, value pdata.AttributeValue) bool {
+			promExemplar.Labels = append(promExemplar.Labels, prompb.Label{
+				Name:  key,
+				Value: value.AsString(),
+			})
+			return true
+		})
+
+		exemplars = append(exemplars, promExemplar)
+	}
+
+	return exemplars
+}
+
+// createAttributes creates a slice of Prometheus labels from the resource's and attribute map's attributes,
+// external labels, and any extra name/value pairs given in extras. A label appearing in more than one source is
+// resolved in the order externalLabels < attributes < resource < extras, with the later source winning: an external
+// label is only added when neither the attributes nor the resource already set that name. Which
+// resource attributes, if any, are promoted onto the series is decided by mapper; pass
+// NewDefaultResourceAttributeMapper() to reproduce the behavior of promoting the resource's own "job"/"instance"
+// attributes verbatim.
+func createAttributes(resource pdata.Resource, attributes pdata.AttributeMap, externalLabels map[string]string, mapper ResourceAttributeMapper, extras ...string) []prompb.Label {
+	// l is keyed by sanitized label name so duplicates from different sources collapse into one label.
+	l := map[string]prompb.Label{}
+
+	attributes.Range(func(key string, value pdata.AttributeValue) bool {
+		name := sanitize(key)
+		l[name] = prompb.Label{Name: name, Value: value.AsString()}
+		return true
+	})
+
+	for _, label := range mapper.MapResourceAttributes(resource) {
+		l[label.Name] = label
+	}
+
+	for key, value := range externalLabels {
+		// External labels have already been sanitized.
+		if _, alreadyExists := l[key]; alreadyExists {
+			// Skip external labels if they are overridden by metric attributes or resource attributes.
+			continue
+		}
+		l[key] = prompb.Label{Name: key, Value: value}
+	}
+
+	for i := 0; i < len(extras)-1; i += 2 {
+		name := sanitize(extras[i])
+		l[name] = prompb.Label{Name: name, Value: extras[i+1]}
+	}
+
+	s := make([]prompb.Label, 0, len(l))
+	for _, lb := range l {
+		s = append(s, lb)
+	}
+
+	return s
+}
+
+// getPromMetricName builds a Prometheus metric name from the metric's own name, prefixed with ns if ns is
+// non-empty.
+func getPromMetricName(metric pdata.Metric, ns string) string {
+	name := metric.Name()
+	if len(ns) > 0 {
+		name = ns + "_" + name
+	}
+
+	return sanitize(name)
+}
+
+// sanitize replaces characters that are invalid in a Prometheus label or metric name with "_", and, if the result
+// would start with a digit or an underscore, prepends "key_"/"key" respectively so the name remains valid.
+func sanitize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+
+	b := []byte(s)
+	for i, r := range s {
+		if !unicode.IsLetter(rune(r)) && !unicode.IsDigit(rune(r)) && r != '_' {
+			b[i] = '_'
+		}
+	}
+	s = string(b)
+
+	if unicode.IsDigit(rune(s[0])) {
+		return keyStr + "_" + s
+	}
+	if s[0] == '_' && len(s) > 1 {
+		return keyStr + s
+	}
+	return s
+}
@@ -81,13 +81,13 @@ func Test_addSample(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		orig     map[string]*prompb.TimeSeries
+		orig     map[uint64]*prompb.TimeSeries
 		testCase []testCase
-		want     map[string]*prompb.TimeSeries
+		want     map[uint64]*prompb.TimeSeries
 	}{
 		{
 			"two_points_same_ts_same_metric",
-			map[string]*prompb.TimeSeries{},
+			map[uint64]*prompb.TimeSeries{},
 			[]testCase{
 				{validMetrics1[validDoubleGauge],
 					getSample(floatVal1, msTime1),
@@ -103,7 +103,7 @@ func Test_addSample(t *testing.T) {
 		},
 		{
 			"two_points_different_ts_same_metric",
-			map[string]*prompb.TimeSeries{},
+			map[uint64]*prompb.TimeSeries{},
 			[]testCase{
 				{validMetrics1[validIntGauge],
 					getSample(float64(intVal1), msTime1),
@@ -118,9 +118,9 @@ func Test_addSample(t *testing.T) {
 		},
 	}
 	t.Run("empty_case", func(t *testing.T) {
-		tsMap := map[string]*prompb.TimeSeries{}
+		tsMap := map[uint64]*prompb.TimeSeries{}
 		addSample(tsMap, nil, nil, pdata.NewMetric())
-		assert.Exactly(t, tsMap, map[string]*prompb.TimeSeries{})
+		assert.Exactly(t, tsMap, map[uint64]*prompb.TimeSeries{})
 	})
 	// run tests
 	for _, tt := range tests {
@@ -132,6 +132,27 @@ func Test_addSample(t *testing.T) {
 	}
 }
 
+// Test_addSample_collision checks that a genuine hash collision - a different label set already occupying the
+// signature's slot - does not drop the new sample, but chains it into the next free slot instead.
+func Test_addSample_collision(t *testing.T) {
+	metric := validMetrics1[validDoubleGauge]
+	sig := timeSeriesSignature(metric, &promLbs1)
+
+	occupant := getTimeSeries(promLbs2, getSample(floatVal2, msTime2))
+	tsMap := map[uint64]*prompb.TimeSeries{sig: occupant}
+
+	sample := getSample(floatVal1, msTime1)
+	addSample(tsMap, &sample, promLbs1, metric)
+
+	// The series already at sig is untouched...
+	assert.Same(t, occupant, tsMap[sig])
+	// ...and the colliding sample landed in the next slot rather than being merged or dropped.
+	chained, ok := tsMap[sig+1]
+	assert.True(t, ok)
+	assert.Equal(t, promLbs1, chained.Labels)
+	assert.Equal(t, []prompb.Sample{sample}, chained.Samples)
+}
+
 // Test_timeSeries checks timeSeriesSignature returns consistent and unique signatures for a distinct label set and
 // metric type combination.
 func Test_timeSeriesSignature(t *testing.T) {
@@ -139,32 +160,35 @@ func Test_timeSeriesSignature(t *testing.T) {
 		name   string
 		lbs    []prompb.Label
 		metric pdata.Metric
-		want   string
+		want   uint64
 	}{
 		{
+			// want is FNV-1a over "IntGauge" + sorted(Label11=Value11, Label12=Value12), computed independently of
+			// timeSeriesSignature so this case can't pass by a broken hash implementation agreeing with itself.
 			"int64_signature",
 			promLbs1,
 			validMetrics1[validIntGauge],
-			validMetrics1[validIntGauge].DataType().String() + lb1Sig,
+			7418794445335860307,
 		},
 		{
+			// want is FNV-1a over "Histogram" + sorted(Label21=Value21, Label22=Value22), computed the same way.
 			"histogram_signature",
 			promLbs2,
 			validMetrics1[validHistogram],
-			validMetrics1[validHistogram].DataType().String() + lb2Sig,
+			7683929497925890723,
 		},
 		{
 			"unordered_signature",
 			getPromLabels(label22, value22, label21, value21),
 			validMetrics1[validHistogram],
-			validMetrics1[validHistogram].DataType().String() + lb2Sig,
+			7683929497925890723,
 		},
 		// descriptor type cannot be nil, as checked by validateMetrics
 		{
 			"nil_case",
 			nil,
 			validMetrics1[validHistogram],
-			validMetrics1[validHistogram].DataType().String(),
+			timeSeriesSignature(validMetrics1[validHistogram], &[]prompb.Label{}),
 		},
 	}
 
@@ -174,6 +198,12 @@ func Test_timeSeriesSignature(t *testing.T) {
 			assert.EqualValues(t, tt.want, timeSeriesSignature(tt.metric, &tt.lbs))
 		})
 	}
+
+	t.Run("different_metric_type_different_signature", func(t *testing.T) {
+		assert.NotEqual(t,
+			timeSeriesSignature(validMetrics1[validIntGauge], &promLbs1),
+			timeSeriesSignature(validMetrics1[validHistogram], &promLbs1))
+	})
 }
 
 // Test_createLabelSet checks resultant label names are sanitized and label in extra overrides label in labels if
@@ -184,6 +214,7 @@ func Test_createLabelSet(t *testing.T) {
 		resource       pdata.Resource
 		orig           pdata.AttributeMap
 		externalLabels map[string]string
+		mapper         ResourceAttributeMapper
 		extras         []string
 		want           []prompb.Label
 	}{
@@ -192,6 +223,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32),
 		},
@@ -203,6 +235,7 @@ func Test_createLabelSet(t *testing.T) {
 			}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32, "job", "prometheus", "instance", "127.0.0.1:8080"),
 		},
@@ -214,6 +247,7 @@ func Test_createLabelSet(t *testing.T) {
 			}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32, "job", "12345", "instance", "true"),
 		},
@@ -222,6 +256,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label11, value31},
 			getPromLabels(label11, value31, label12, value12),
 		},
@@ -230,6 +265,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1Dirty,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31 + dirty1, value31, label32, value32},
 			getPromLabels(label11+"_", value11, "key_"+label12, value12, label31+"_", value31, label32, value32),
 		},
@@ -238,6 +274,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			pdata.NewAttributeMap(),
 			nil,
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label31, value31, label32, value32),
 		},
@@ -246,6 +283,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{"", ""},
 			getPromLabels(label11, value11, label12, value12, "", ""),
 		},
@@ -254,6 +292,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			map[string]string{},
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32},
 			getPromLabels(label11, value11, label12, value12, label31, value31),
 		},
@@ -262,6 +301,7 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			exlbs1,
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label41, value41, label31, value31, label32, value32),
 		},
@@ -270,18 +310,101 @@ func Test_createLabelSet(t *testing.T) {
 			getResource(map[string]pdata.AttributeValue{}),
 			lbs1,
 			exlbs2,
+			NewDefaultResourceAttributeMapper(),
 			[]string{label31, value31, label32, value32},
 			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32),
 		},
+		{
+			"promote_list_promotes_whitelisted_keys",
+			getResource(map[string]pdata.AttributeValue{
+				"k8s.namespace.name": pdata.NewAttributeValueString("default"),
+				"k8s.pod.name":       pdata.NewAttributeValueString("my-pod"),
+				"not.promoted":       pdata.NewAttributeValueString("ignored"),
+			}),
+			lbs1,
+			map[string]string{},
+			NewPromoteListResourceAttributeMapper([]string{"k8s.namespace.name", "k8s.pod.name"}),
+			[]string{label31, value31, label32, value32},
+			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32, "k8s_namespace_name", "default", "k8s_pod_name", "my-pod"),
+		},
+		{
+			"promote_list_resource_attribute_overrides_datapoint_attribute_of_same_name",
+			getResource(map[string]pdata.AttributeValue{
+				label11: pdata.NewAttributeValueString("from_resource"),
+			}),
+			lbs1,
+			map[string]string{},
+			NewPromoteListResourceAttributeMapper([]string{label11}),
+			[]string{label31, value31, label32, value32},
+			getPromLabels(label11, "from_resource", label12, value12, label31, value31, label32, value32),
+		},
+		{
+			"target_info_promotes_only_service_name_and_instance",
+			getResource(map[string]pdata.AttributeValue{
+				"service.name":        pdata.NewAttributeValueString("my-service"),
+				"service.instance.id": pdata.NewAttributeValueString("instance-1"),
+				"k8s.namespace.name":  pdata.NewAttributeValueString("default"),
+			}),
+			lbs1,
+			map[string]string{},
+			NewTargetInfoResourceAttributeMapper(),
+			[]string{label31, value31, label32, value32},
+			getPromLabels(label11, value11, label12, value12, label31, value31, label32, value32, "job", "my-service", "instance", "instance-1"),
+		},
+		{
+			"target_info_resource_attribute_overrides_datapoint_attribute_of_same_name",
+			getResource(map[string]pdata.AttributeValue{
+				"service.name": pdata.NewAttributeValueString(value11),
+			}),
+			getAttributeMap(map[string]string{
+				"job": "from_datapoint",
+			}),
+			map[string]string{},
+			NewTargetInfoResourceAttributeMapper(),
+			nil,
+			getPromLabels("job", value11),
+		},
 	}
 	// run tests
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.ElementsMatch(t, tt.want, createAttributes(tt.resource, tt.orig, tt.externalLabels, tt.extras...))
+			assert.ElementsMatch(t, tt.want, createAttributes(tt.resource, tt.orig, tt.externalLabels, tt.mapper, tt.extras...))
 		})
 	}
 }
 
+// Test_targetInfoResourceAttributeMapper_TargetInfo checks the target_info strategy's separate info series: every
+// resource attribute becomes a label (sanitized), plus a __name__ label identifying the series itself.
+func Test_targetInfoResourceAttributeMapper_TargetInfo(t *testing.T) {
+	mapper := NewTargetInfoResourceAttributeMapper()
+
+	t.Run("empty_resource_has_no_target_info", func(t *testing.T) {
+		_, ok := mapper.TargetInfo(getResource(map[string]pdata.AttributeValue{}))
+		assert.False(t, ok)
+	})
+
+	t.Run("populated_resource_carries_all_attributes", func(t *testing.T) {
+		labels, ok := mapper.TargetInfo(getResource(map[string]pdata.AttributeValue{
+			"service.name": pdata.NewAttributeValueString("my-service"),
+			"k8s.pod.name": pdata.NewAttributeValueString("my-pod"),
+		}))
+		assert.True(t, ok)
+		assert.ElementsMatch(t, getPromLabels("service_name", "my-service", "k8s_pod_name", "my-pod", nameLabel, targetInfoMetricName), labels)
+	})
+}
+
+// Test_otherStrategies_have_no_TargetInfo checks that the default and promote-list strategies never emit a
+// target_info series.
+func Test_otherStrategies_have_no_TargetInfo(t *testing.T) {
+	resource := getResource(map[string]pdata.AttributeValue{"service.name": pdata.NewAttributeValueString("my-service")})
+
+	_, ok := NewDefaultResourceAttributeMapper().TargetInfo(resource)
+	assert.False(t, ok)
+
+	_, ok = NewPromoteListResourceAttributeMapper([]string{"service.name"}).TargetInfo(resource)
+	assert.False(t, ok)
+}
+
 // Tes_getPromMetricName checks if OTLP metric names are converted to Cortex metric names correctly.
 // Test cases are empty namespace, monotonic metrics that require a total suffix, and metric names that contains
 // invalid characters.
@@ -342,20 +465,20 @@ func Test_addExemplars(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		orig     map[string]*prompb.TimeSeries
+		orig     map[uint64]*prompb.TimeSeries
 		testCase []testCase
-		want     map[string]*prompb.TimeSeries
+		want     map[uint64]*prompb.TimeSeries
 	}{
 		{
 			"timeSeries_is_empty",
-			map[string]*prompb.TimeSeries{},
+			map[uint64]*prompb.TimeSeries{},
 			[]testCase{
 				{
 					[]prompb.Exemplar{getExemplar(float64(intVal1), msTime1)},
 					getBucketBoundsData([]float64{1, 2, 3}),
 				},
 			},
-			map[string]*prompb.TimeSeries{},
+			map[uint64]*prompb.TimeSeries{},
 		},
 		{
 			"timeSeries_without_sample",
@@ -370,7 +493,7 @@ func Test_addExemplars(t *testing.T) {
 		},
 		{
 			"exemplar_value_less_than_bucket_bound",
-			map[string]*prompb.TimeSeries{
+			map[uint64]*prompb.TimeSeries{
 				lb1Sig: getTimeSeries(getPromLabels(label11, value11, label12, value12),
 					getSample(float64(intVal1), msTime1)),
 			},
@@ -384,7 +507,7 @@ func Test_addExemplars(t *testing.T) {
 		},
 		{
 			"infinite_bucket_bound",
-			map[string]*prompb.TimeSeries{
+			map[uint64]*prompb.TimeSeries{
 				lb1Sig: getTimeSeries(getPromLabels(label11, value11, label12, value12),
 					getSample(float64(intVal1), msTime1)),
 			},
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// maxPromSchema and minPromSchema are the bounds of the exponent Prometheus native histograms support; OTel's
+// ExponentialHistogram scale is clamped into this range since it maps directly onto the Prometheus schema.
+const (
+	maxPromSchema = 8
+	minPromSchema = -4
+)
+
+// addExponentialHistogramDataPoints converts every point in dps into a Prometheus native histogram and adds it,
+// keyed by the usual timeSeriesSignature, to tsMap. It follows the same labels-plus-metric pattern as addSample,
+// including chaining forward on a genuine hash collision, but populates the TimeSeries' Histograms field instead
+// of Samples.
+func addExponentialHistogramDataPoints(tsMap map[uint64]*prompb.TimeSeries, dataPoints pdata.ExponentialHistogramDataPointSlice, metric pdata.Metric, baseLabels []prompb.Label) {
+	temporality := metric.ExponentialHistogram().AggregationTemporality()
+
+	for i := 0; i < dataPoints.Len(); i++ {
+		pt := dataPoints.At(i)
+
+		histogram := exponentialHistogramToPromHistogram(pt, temporality)
+		histogram.Timestamp = timestamp.FromTime(pt.Timestamp().AsTime())
+		exemplars := getPromExemplarsFromExponentialHistogram(pt)
+
+		for sig := timeSeriesSignature(metric, &baseLabels); ; sig++ {
+			ts, ok := tsMap[sig]
+			if !ok {
+				tsMap[sig] = &prompb.TimeSeries{
+					Labels:     baseLabels,
+					Histograms: []prompb.Histogram{histogram},
+					Exemplars:  exemplars,
+				}
+				break
+			}
+			if labelsMatch(ts.Labels, baseLabels) {
+				ts.Histograms = append(ts.Histograms, histogram)
+				ts.Exemplars = append(ts.Exemplars, exemplars...)
+				break
+			}
+		}
+	}
+}
+
+// exponentialHistogramToPromHistogram converts a single OTel exponential histogram data point into the sparse
+// (native) Prometheus histogram wire format.
+func exponentialHistogramToPromHistogram(p pdata.ExponentialHistogramDataPoint, temporality pdata.MetricAggregationTemporality) prompb.Histogram {
+	scale := p.Scale()
+	if scale > maxPromSchema {
+		scale = maxPromSchema
+	} else if scale < minPromSchema {
+		scale = minPromSchema
+	}
+
+	positiveSpans, positiveDeltas := convertBucketsLayout(p.Positive().Offset(), p.Positive().BucketCounts())
+	negativeSpans, negativeDeltas := convertBucketsLayout(p.Negative().Offset(), p.Negative().BucketCounts())
+
+	resetHint := prompb.Histogram_UNKNOWN
+	if temporality == pdata.MetricAggregationTemporalityDelta {
+		resetHint = prompb.Histogram_GAUGE
+	}
+
+	return prompb.Histogram{
+		Count:          &prompb.Histogram_CountFloat{CountFloat: float64(p.Count())},
+		Sum:            p.Sum(),
+		Schema:         scale,
+		ZeroThreshold:  p.ZeroThreshold(),
+		ZeroCount:      &prompb.Histogram_ZeroCountFloat{ZeroCountFloat: float64(p.ZeroCount())},
+		PositiveSpans:  positiveSpans,
+		PositiveDeltas: positiveDeltas,
+		NegativeSpans:  negativeSpans,
+		NegativeDeltas: negativeDeltas,
+		ResetHint:      resetHint,
+	}
+}
+
+// convertBucketsLayout walks an OTel exponential histogram bucket-count slice (offset plus contiguous per-bucket
+// counts) and re-encodes it as Prometheus' span+delta layout: a new BucketSpan starts whenever a run of zero-count
+// buckets separates two populated buckets, and each populated bucket's count is stored as the delta from the
+// previous populated bucket's count.
+func convertBucketsLayout(offset int32, counts []uint64) ([]prompb.BucketSpan, []int64) {
+	if len(counts) == 0 {
+		return nil, nil
+	}
+
+	var spans []prompb.BucketSpan
+	var deltas []int64
+
+	prevCount := int64(0)
+	nextIdx := int32(0) // absolute bucket index one past the last populated bucket; 0 until the first span starts
+	inSpan := false
+	var span prompb.BucketSpan
+
+	for i, c := range counts {
+		idx := offset + int32(i)
+		if c == 0 {
+			if inSpan {
+				spans = append(spans, span)
+				inSpan = false
+			}
+			continue
+		}
+
+		if !inSpan {
+			span = prompb.BucketSpan{Offset: idx - nextIdx, Length: 0}
+			inSpan = true
+		}
+		span.Length++
+		count := int64(c)
+		deltas = append(deltas, count-prevCount)
+		prevCount = count
+		nextIdx = idx + 1
+	}
+	if inSpan {
+		spans = append(spans, span)
+	}
+
+	return spans, deltas
+}
@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newExponentialHistogramMetric(name string, temporality pdata.MetricAggregationTemporality) pdata.Metric {
+	metric := pdata.NewMetric()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeExponentialHistogram)
+	metric.ExponentialHistogram().SetAggregationTemporality(temporality)
+	return metric
+}
+
+func newExponentialHistogramDataPoints(count uint64, ts pdata.Timestamp) pdata.ExponentialHistogramDataPointSlice {
+	dps := pdata.NewExponentialHistogramDataPointSlice()
+	dp := dps.AppendEmpty()
+	dp.SetCount(count)
+	dp.SetTimestamp(ts)
+	return dps
+}
+
+// Test_addExponentialHistogramDataPoints checks that a point is inserted as a new series keyed by the usual
+// timeSeriesSignature, and that a genuine hash collision with a pre-existing, differently-labeled series is
+// chained into the next free slot rather than being merged into it.
+func Test_addExponentialHistogramDataPoints(t *testing.T) {
+	t.Run("inserts_new_series", func(t *testing.T) {
+		metric := newExponentialHistogramMetric("latency", pdata.MetricAggregationTemporalityCumulative)
+		dps := newExponentialHistogramDataPoints(10, pdata.Timestamp(msTime1))
+		tsMap := map[uint64]*prompb.TimeSeries{}
+
+		addExponentialHistogramDataPoints(tsMap, dps, metric, promLbs1)
+
+		sig := timeSeriesSignature(metric, &promLbs1)
+		ts, ok := tsMap[sig]
+		assert.True(t, ok)
+		assert.Equal(t, promLbs1, ts.Labels)
+		assert.Len(t, ts.Histograms, 1)
+	})
+
+	t.Run("collision_chains_to_next_slot", func(t *testing.T) {
+		metric := newExponentialHistogramMetric("latency", pdata.MetricAggregationTemporalityCumulative)
+		sig := timeSeriesSignature(metric, &promLbs1)
+
+		occupant := getTimeSeries(promLbs2)
+		tsMap := map[uint64]*prompb.TimeSeries{sig: occupant}
+
+		dps := newExponentialHistogramDataPoints(10, pdata.Timestamp(msTime1))
+		addExponentialHistogramDataPoints(tsMap, dps, metric, promLbs1)
+
+		// The series already at sig is untouched...
+		assert.Same(t, occupant, tsMap[sig])
+		assert.Empty(t, occupant.Histograms)
+		// ...and the colliding histogram landed in the next slot rather than being merged or dropped.
+		chained, ok := tsMap[sig+1]
+		assert.True(t, ok)
+		assert.Equal(t, promLbs1, chained.Labels)
+		assert.Len(t, chained.Histograms, 1)
+	})
+}
+
+// Test_convertBucketsLayout checks the span+delta re-encoding of OTel's offset-plus-contiguous-counts bucket
+// layout, including the empty, no-gap and gapped cases.
+func Test_convertBucketsLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		offset     int32
+		counts     []uint64
+		wantSpans  []prompb.BucketSpan
+		wantDeltas []int64
+	}{
+		{
+			"empty_buckets",
+			0,
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"zero_only_buckets",
+			0,
+			[]uint64{0, 0, 0},
+			nil,
+			nil,
+		},
+		{
+			"no_gap",
+			2,
+			[]uint64{1, 3, 2},
+			[]prompb.BucketSpan{{Offset: 2, Length: 3}},
+			[]int64{1, 2, -1},
+		},
+		{
+			"gap_between_populated_buckets",
+			0,
+			[]uint64{1, 0, 0, 2, 0, 5},
+			[]prompb.BucketSpan{{Offset: 0, Length: 1}, {Offset: 2, Length: 1}, {Offset: 1, Length: 1}},
+			[]int64{1, 1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSpans, gotDeltas := convertBucketsLayout(tt.offset, tt.counts)
+			assert.Equal(t, tt.wantSpans, gotSpans)
+			assert.Equal(t, tt.wantDeltas, gotDeltas)
+		})
+	}
+}
+
+// Test_exponentialHistogramToPromHistogram checks the data point to prompb.Histogram conversion, in particular
+// schema clamping and the reset hint routing by temporality.
+func Test_exponentialHistogramToPromHistogram(t *testing.T) {
+	tests := []struct {
+		name        string
+		scale       int32
+		temporality pdata.MetricAggregationTemporality
+		wantSchema  int32
+		wantHint    prompb.Histogram_ResetHint
+	}{
+		{"cumulative_in_range", 3, pdata.MetricAggregationTemporalityCumulative, 3, prompb.Histogram_UNKNOWN},
+		{"delta_in_range", 3, pdata.MetricAggregationTemporalityDelta, 3, prompb.Histogram_GAUGE},
+		{"clamped_high", 20, pdata.MetricAggregationTemporalityCumulative, maxPromSchema, prompb.Histogram_UNKNOWN},
+		{"clamped_low", -20, pdata.MetricAggregationTemporalityCumulative, minPromSchema, prompb.Histogram_UNKNOWN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pdata.NewExponentialHistogramDataPoint()
+			p.SetScale(tt.scale)
+			p.SetCount(10)
+			p.SetSum(42)
+			p.SetZeroCount(1)
+
+			h := exponentialHistogramToPromHistogram(p, tt.temporality)
+			assert.Equal(t, tt.wantSchema, h.Schema)
+			assert.Equal(t, tt.wantHint, h.ResetHint)
+			assert.Equal(t, &prompb.Histogram_CountFloat{CountFloat: 10}, h.Count)
+			assert.Equal(t, 42.0, h.Sum)
+		})
+	}
+}
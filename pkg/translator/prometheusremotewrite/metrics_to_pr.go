@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"time"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// Settings configures FromMetrics' translation of a pdata.Metrics batch into Prometheus remote-write time series.
+// StartTimeAdjuster and StalenessTracker are optional: leaving either nil skips the behavior it provides.
+// ResourceAttributeMapper defaults to NewDefaultResourceAttributeMapper() if left nil.
+type Settings struct {
+	Namespace               string
+	ExternalLabels          map[string]string
+	ResourceAttributeMapper ResourceAttributeMapper
+	StartTimeAdjuster       *StartTimeAdjuster
+	StalenessTracker        *StalenessTracker
+}
+
+// FromMetrics converts md into Prometheus remote-write time series keyed by timeSeriesSignature. now is used as
+// the sample timestamp for any target_info series settings' ResourceAttributeMapper produces, and as the
+// reference time for evaluating staleness against settings' StalenessTracker.
+//
+// Classic (explicit-bucket) Histogram and Summary metrics are validated but not expanded into their constituent
+// _sum/_count/_bucket series here: this snapshot has no bucket-expansion helper for them, unlike
+// addExponentialHistogramDataPoints for native histograms, so they are silently skipped below.
+func FromMetrics(md pdata.Metrics, settings Settings, now time.Time) map[uint64]*prompb.TimeSeries {
+	mapper := settings.ResourceAttributeMapper
+	if mapper == nil {
+		mapper = NewDefaultResourceAttributeMapper()
+	}
+
+	var resetSigs map[uint64]bool
+	if settings.StartTimeAdjuster != nil {
+		resetSigs = make(map[uint64]bool)
+		for _, sig := range settings.StartTimeAdjuster.AdjustMetrics(md) {
+			resetSigs[sig] = true
+		}
+	}
+
+	tsMap := map[uint64]*prompb.TimeSeries{}
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := rm.Resource()
+
+		if targetLabels, ok := mapper.TargetInfo(resource); ok {
+			addSample(tsMap, &prompb.Sample{Value: 1, Timestamp: timestamp.FromTime(now)}, targetLabels, targetInfoMetric())
+		}
+
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				if !validateMetrics(metric) {
+					continue
+				}
+				addMetric(tsMap, resource, metric, settings, mapper, resetSigs)
+			}
+		}
+	}
+
+	if settings.StalenessTracker != nil {
+		// Track must run before EmitStaleMarkers, and only once: EmitStaleMarkers removes a series from the
+		// tracker as soon as it emits a marker for it, so tracking this call's (now marker-enriched) tsMap
+		// afterwards would re-add those entries and defeat the "mark once" guarantee.
+		settings.StalenessTracker.Track(tsMap)
+		settings.StalenessTracker.EmitStaleMarkers(tsMap, now)
+	}
+
+	return tsMap
+}
+
+// targetInfoMetric returns the synthetic metric identity the target_info series is hashed under, so it gets a
+// timeSeriesSignature like any other series.
+func targetInfoMetric() pdata.Metric {
+	metric := pdata.NewMetric()
+	metric.SetName(targetInfoMetricName)
+	metric.SetDataType(pdata.MetricDataTypeDoubleGauge)
+	return metric
+}
+
+// stringMapToAttributeMap adapts a data point's StringMap of attributes to the AttributeMap createAttributes
+// expects.
+func stringMapToAttributeMap(sm pdata.StringMap) pdata.AttributeMap {
+	am := pdata.NewAttributeMap()
+	sm.Range(func(k, v string) bool {
+		am.InsertString(k, v)
+		return true
+	})
+	return am
+}
+
+func addMetric(tsMap map[uint64]*prompb.TimeSeries, resource pdata.Resource, metric pdata.Metric, settings Settings, mapper ResourceAttributeMapper, resetSigs map[uint64]bool) {
+	name := getPromMetricName(metric, settings.Namespace)
+
+	switch metric.DataType() {
+	case pdata.MetricDataTypeIntGauge:
+		dps := metric.IntGauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := createAttributes(resource, stringMapToAttributeMap(dp.LabelsMap()), settings.ExternalLabels, mapper, nameLabel, name)
+			addSample(tsMap, &prompb.Sample{Value: float64(dp.Value()), Timestamp: timestamp.FromTime(dp.Timestamp().AsTime())}, labels, metric)
+		}
+	case pdata.MetricDataTypeDoubleGauge:
+		dps := metric.DoubleGauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := createAttributes(resource, stringMapToAttributeMap(dp.LabelsMap()), settings.ExternalLabels, mapper, nameLabel, name)
+			addSample(tsMap, &prompb.Sample{Value: dp.Value(), Timestamp: timestamp.FromTime(dp.Timestamp().AsTime())}, labels, metric)
+		}
+	case pdata.MetricDataTypeIntSum:
+		dps := metric.IntSum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := createAttributes(resource, stringMapToAttributeMap(dp.LabelsMap()), settings.ExternalLabels, mapper, nameLabel, name)
+			addResetSampleIfNeeded(tsMap, settings, metric, dp.LabelsMap(), labels, resetSigs)
+			addSample(tsMap, &prompb.Sample{Value: float64(dp.Value()), Timestamp: timestamp.FromTime(dp.Timestamp().AsTime())}, labels, metric)
+		}
+	case pdata.MetricDataTypeDoubleSum:
+		dps := metric.DoubleSum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := createAttributes(resource, stringMapToAttributeMap(dp.LabelsMap()), settings.ExternalLabels, mapper, nameLabel, name)
+			addResetSampleIfNeeded(tsMap, settings, metric, dp.LabelsMap(), labels, resetSigs)
+			addSample(tsMap, &prompb.Sample{Value: dp.Value(), Timestamp: timestamp.FromTime(dp.Timestamp().AsTime())}, labels, metric)
+		}
+	case pdata.MetricDataTypeExponentialHistogram:
+		// AdjustMetrics does not track exponential histograms (see its switch in start_time_adjuster.go), so there
+		// is never a reset signature to look up here.
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			labels := createAttributes(resource, stringMapToAttributeMap(dp.LabelsMap()), settings.ExternalLabels, mapper, nameLabel, name)
+			single := pdata.NewExponentialHistogramDataPointSlice()
+			dp.CopyTo(single.AppendEmpty())
+			addExponentialHistogramDataPoints(tsMap, single, metric, labels)
+		}
+	}
+}
+
+// addResetSampleIfNeeded adds the synthetic zero-valued reset sample StartTimeAdjuster produced for dpLabels, if
+// AdjustMetrics detected a reset for this exact data point during the call that preceded this translation.
+func addResetSampleIfNeeded(tsMap map[uint64]*prompb.TimeSeries, settings Settings, metric pdata.Metric, dpLabels pdata.StringMap, fullLabels []prompb.Label, resetSigs map[uint64]bool) {
+	if settings.StartTimeAdjuster == nil || len(resetSigs) == 0 {
+		return
+	}
+
+	ptSig := settings.StartTimeAdjuster.pointSignature(metric, dpLabels)
+	if !resetSigs[ptSig] {
+		return
+	}
+
+	sample := settings.StartTimeAdjuster.ResetSample(ptSig)
+	addSample(tsMap, &sample, fullLabels, metric)
+}
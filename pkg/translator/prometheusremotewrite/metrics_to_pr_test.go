@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newIntSumMetricsForTranslation(name string, monotonic bool, start, ts pdata.Timestamp, value int64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeIntSum)
+	metric.IntSum().SetIsMonotonic(monotonic)
+	dp := metric.IntSum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetValue(value)
+	return md
+}
+
+func newIntSumMetricsWithResource(resourceAttrs map[string]string, name string, monotonic bool, start, ts pdata.Timestamp, value int64) pdata.Metrics {
+	md := newIntSumMetricsForTranslation(name, monotonic, start, ts, value)
+	resource := md.ResourceMetrics().At(0).Resource()
+	for k, v := range resourceAttrs {
+		resource.Attributes().InsertString(k, v)
+	}
+	return md
+}
+
+// Test_FromMetrics_ResetSampleWiring checks that a counter reset detected by the configured StartTimeAdjuster
+// shows up in FromMetrics' output as a synthetic zero-valued sample alongside the real one, rather than
+// StartTimeAdjuster only ever being exercised from its own unit tests.
+func Test_FromMetrics_ResetSampleWiring(t *testing.T) {
+	settings := Settings{StartTimeAdjuster: NewStartTimeAdjuster(time.Hour)}
+
+	first := newIntSumMetricsForTranslation("requests_total", true, 0, 1000, 10)
+	FromMetrics(first, settings, time.Unix(0, 1000))
+
+	resetBatch := newIntSumMetricsForTranslation("requests_total", true, 0, 2000, 3)
+	tsMap := FromMetrics(resetBatch, settings, time.Unix(0, 2000))
+
+	assert.Len(t, tsMap, 1)
+	for _, ts := range tsMap {
+		assert.Len(t, ts.Samples, 2)
+		assert.Equal(t, 0.0, ts.Samples[0].Value)
+		assert.Equal(t, 3.0, ts.Samples[1].Value)
+	}
+}
+
+// Test_FromMetrics_NoAdjuster checks that FromMetrics works without a StartTimeAdjuster configured: data points
+// are translated as-is, with no reset samples injected.
+func Test_FromMetrics_NoAdjuster(t *testing.T) {
+	md := newIntSumMetricsForTranslation("requests_total", true, 1000, 2000, 10)
+
+	tsMap := FromMetrics(md, Settings{}, time.Unix(0, 2000))
+
+	assert.Len(t, tsMap, 1)
+	for _, ts := range tsMap {
+		assert.Len(t, ts.Samples, 1)
+		assert.Equal(t, 10.0, ts.Samples[0].Value)
+	}
+}
+
+// Test_FromMetrics_TargetInfo checks that, when the configured ResourceAttributeMapper produces one, FromMetrics
+// emits a target_info series carrying the resource's attributes, rather than TargetInfo going uncalled outside
+// ResourceAttributeMapper's own unit tests.
+func Test_FromMetrics_TargetInfo(t *testing.T) {
+	md := newIntSumMetricsWithResource(map[string]string{serviceNameAttr: "my-service"}, "requests_total", true, 0, 1000, 10)
+
+	tsMap := FromMetrics(md, Settings{ResourceAttributeMapper: NewTargetInfoResourceAttributeMapper()}, time.Unix(0, 2000))
+
+	var found *prompb.TimeSeries
+	for _, ts := range tsMap {
+		for _, l := range ts.Labels {
+			if l.Name == nameLabel && l.Value == targetInfoMetricName {
+				found = ts
+			}
+		}
+	}
+	if assert.NotNil(t, found) {
+		assert.Len(t, found.Samples, 1)
+		assert.Equal(t, 1.0, found.Samples[0].Value)
+	}
+}
+
+// Test_FromMetrics_DefaultMapper checks that, with no ResourceAttributeMapper configured, FromMetrics falls back
+// to NewDefaultResourceAttributeMapper() and so emits no target_info series.
+func Test_FromMetrics_DefaultMapper(t *testing.T) {
+	md := newIntSumMetricsWithResource(map[string]string{serviceNameAttr: "my-service"}, "requests_total", true, 0, 1000, 10)
+
+	tsMap := FromMetrics(md, Settings{}, time.Unix(0, 2000))
+
+	for _, ts := range tsMap {
+		for _, l := range ts.Labels {
+			assert.NotEqual(t, targetInfoMetricName, l.Value)
+		}
+	}
+}
+
+// Test_FromMetrics_StalenessMarker checks that a series present in one FromMetrics call but absent from a later
+// one, with a shared StalenessTracker, gets a stale marker appended to that later call's output, rather than
+// StalenessTracker only ever being exercised from its own unit tests.
+func Test_FromMetrics_StalenessMarker(t *testing.T) {
+	settings := Settings{StalenessTracker: NewStalenessTracker(time.Hour)}
+
+	present := newIntSumMetricsForTranslation("requests_total", true, 0, 1000, 10)
+	firstMap := FromMetrics(present, settings, time.Unix(0, 1000))
+	assert.Len(t, firstMap, 1)
+	var sig uint64
+	for s := range firstMap {
+		sig = s
+	}
+
+	missing := pdata.NewMetrics()
+	secondMap := FromMetrics(missing, settings, time.Unix(0, 2000))
+
+	if assert.Contains(t, secondMap, sig) {
+		assert.Len(t, secondMap[sig].Samples, 1)
+		assert.Equal(t, math.Float64frombits(staleNaN), secondMap[sig].Samples[0].Value)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+const (
+	serviceNameAttr       = "service.name"
+	serviceInstanceIDAttr = "service.instance.id"
+
+	// targetInfoMetricName is the OpenMetrics info-metric convention for a series that exists only to carry a
+	// resource's attributes as labels, rather than to report a value.
+	targetInfoMetricName = "target_info"
+)
+
+// ResourceAttributeMapper decides which labels, if any, a resource's attributes are promoted to on the data
+// series derived from it. createAttributes calls MapResourceAttributes for every series; translation entry points
+// additionally call TargetInfo once per resource to decide whether a separate target_info series should be
+// emitted alongside the data series.
+type ResourceAttributeMapper interface {
+	// MapResourceAttributes returns the labels this strategy promotes from resource onto each of its data series.
+	MapResourceAttributes(resource pdata.Resource) []prompb.Label
+
+	// TargetInfo returns the labels for a target_info series built from resource, and ok=false for strategies
+	// that don't produce one.
+	TargetInfo(resource pdata.Resource) (labels []prompb.Label, ok bool)
+}
+
+// defaultResourceAttributeMapper reproduces createAttributes' original behavior: the resource's own "job" and
+// "instance" attributes, if present, are promoted verbatim.
+type defaultResourceAttributeMapper struct{}
+
+// NewDefaultResourceAttributeMapper returns the ResourceAttributeMapper createAttributes used before resource
+// attribute mapping became pluggable.
+func NewDefaultResourceAttributeMapper() ResourceAttributeMapper {
+	return defaultResourceAttributeMapper{}
+}
+
+func (defaultResourceAttributeMapper) MapResourceAttributes(resource pdata.Resource) []prompb.Label {
+	var labels []prompb.Label
+	if job, ok := resource.Attributes().Get(jobStr); ok {
+		labels = append(labels, prompb.Label{Name: jobStr, Value: job.AsString()})
+	}
+	if instance, ok := resource.Attributes().Get(instanceStr); ok {
+		labels = append(labels, prompb.Label{Name: instanceStr, Value: instance.AsString()})
+	}
+	return labels
+}
+
+func (defaultResourceAttributeMapper) TargetInfo(pdata.Resource) ([]prompb.Label, bool) {
+	return nil, false
+}
+
+// promoteListResourceAttributeMapper promotes a caller-supplied whitelist of resource attribute keys verbatim,
+// sanitizing each key into a valid label name.
+type promoteListResourceAttributeMapper struct {
+	keys []string
+}
+
+// NewPromoteListResourceAttributeMapper returns a ResourceAttributeMapper that promotes exactly the resource
+// attributes named in keys, e.g. "k8s.namespace.name" becomes the label "k8s_namespace_name".
+func NewPromoteListResourceAttributeMapper(keys []string) ResourceAttributeMapper {
+	return promoteListResourceAttributeMapper{keys: keys}
+}
+
+func (m promoteListResourceAttributeMapper) MapResourceAttributes(resource pdata.Resource) []prompb.Label {
+	var labels []prompb.Label
+	for _, key := range m.keys {
+		if value, ok := resource.Attributes().Get(key); ok {
+			labels = append(labels, prompb.Label{Name: sanitize(key), Value: value.AsString()})
+		}
+	}
+	return labels
+}
+
+func (promoteListResourceAttributeMapper) TargetInfo(pdata.Resource) ([]prompb.Label, bool) {
+	return nil, false
+}
+
+// targetInfoResourceAttributeMapper follows the OpenMetrics info-metric convention: only service.name and
+// service.instance.id are promoted onto each data series (as "job" and "instance"), while the resource's full
+// attribute set is instead carried by a single separate target_info series.
+type targetInfoResourceAttributeMapper struct{}
+
+// NewTargetInfoResourceAttributeMapper returns a ResourceAttributeMapper implementing the OpenMetrics target_info
+// convention.
+func NewTargetInfoResourceAttributeMapper() ResourceAttributeMapper {
+	return targetInfoResourceAttributeMapper{}
+}
+
+func (targetInfoResourceAttributeMapper) MapResourceAttributes(resource pdata.Resource) []prompb.Label {
+	var labels []prompb.Label
+	if name, ok := resource.Attributes().Get(serviceNameAttr); ok {
+		labels = append(labels, prompb.Label{Name: jobStr, Value: name.AsString()})
+	}
+	if id, ok := resource.Attributes().Get(serviceInstanceIDAttr); ok {
+		labels = append(labels, prompb.Label{Name: instanceStr, Value: id.AsString()})
+	}
+	return labels
+}
+
+func (targetInfoResourceAttributeMapper) TargetInfo(resource pdata.Resource) ([]prompb.Label, bool) {
+	if resource.Attributes().Len() == 0 {
+		return nil, false
+	}
+
+	var labels []prompb.Label
+	resource.Attributes().Range(func(key string, value pdata.AttributeValue) bool {
+		labels = append(labels, prompb.Label{Name: sanitize(key), Value: value.AsString()})
+		return true
+	})
+	labels = append(labels, prompb.Label{Name: nameLabel, Value: targetInfoMetricName})
+	return labels, true
+}
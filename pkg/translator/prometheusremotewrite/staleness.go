@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// staleNaN is the bit pattern Prometheus uses to mark a sample as stale. See
+// https://github.com/prometheus/prometheus/blob/main/model/value/value.go.
+const staleNaN uint64 = 0x7ff0000000000002
+
+// trackedSeries is the bookkeeping a StalenessTracker keeps per series signature between translation calls.
+type trackedSeries struct {
+	labels   []prompb.Label
+	lastSeen time.Time
+}
+
+// StalenessTracker remembers, across successive translation calls, which series (keyed by the same signature
+// addSample uses) were present in the most recent batch. When a previously-seen series is absent from a later
+// batch, EmitStaleMarkers appends a Prometheus staleness marker for it so that OTLP-sourced series get the same
+// end-to-end staleness semantics the Prometheus scrape path produces natively. Series that are not seen again
+// within ttl are evicted and stop being tracked.
+type StalenessTracker struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	seen map[uint64]trackedSeries
+}
+
+// NewStalenessTracker returns a StalenessTracker that evicts a series once ttl has elapsed since it was last seen.
+func NewStalenessTracker(ttl time.Duration) *StalenessTracker {
+	return &StalenessTracker{
+		ttl:  ttl,
+		seen: make(map[uint64]trackedSeries),
+	}
+}
+
+// Track records every series present in tsMap as seen as of now, so a later EmitStaleMarkers call can detect when
+// it goes missing.
+func (st *StalenessTracker) Track(tsMap map[uint64]*prompb.TimeSeries) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	for sig, ts := range tsMap {
+		st.seen[sig] = trackedSeries{labels: ts.Labels, lastSeen: now}
+	}
+}
+
+// EmitStaleMarkers adds a stale sample, timestamped at now, to tsMap for every tracked series that is missing from
+// tsMap for the first time, then stops tracking it - a series is marked stale once, at the moment it disappears,
+// not on every subsequent call while it remains missing. It also evicts any tracked series that has been missing
+// for longer than ttl without ever being re-seen by Track. It does not modify series already present in tsMap.
+func (st *StalenessTracker) EmitStaleMarkers(tsMap map[uint64]*prompb.TimeSeries, now time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	ts := timestamp.FromTime(now)
+	for sig, tracked := range st.seen {
+		if _, present := tsMap[sig]; present {
+			continue
+		}
+		delete(st.seen, sig)
+		if now.Sub(tracked.lastSeen) > st.ttl {
+			continue
+		}
+		tsMap[sig] = &prompb.TimeSeries{
+			Labels: tracked.labels,
+			Samples: []prompb.Sample{
+				{Value: math.Float64frombits(staleNaN), Timestamp: ts},
+			},
+		}
+	}
+}
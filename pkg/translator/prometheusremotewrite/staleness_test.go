@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test_StalenessTracker_EmitStaleMarkers checks that a series tracked in one batch and missing from the next gets
+// a stale marker at the correct timestamp and with the Prometheus staleness NaN bit pattern, that a series which
+// reappears is left untouched, and that a series is no longer marked stale once it has aged out past the TTL.
+func Test_StalenessTracker_EmitStaleMarkers(t *testing.T) {
+	now := time.Now()
+
+	t.Run("missing_series_gets_stale_marker", func(t *testing.T) {
+		tracker := NewStalenessTracker(time.Minute)
+		sig := timeSeriesSignature(validMetrics1[validDoubleGauge], &promLbs1)
+
+		prev := map[uint64]*prompb.TimeSeries{
+			sig: getTimeSeries(promLbs1, getSample(floatVal1, msTime1)),
+		}
+		tracker.Track(prev)
+
+		curr := map[uint64]*prompb.TimeSeries{}
+		tracker.EmitStaleMarkers(curr, now)
+
+		ts, ok := curr[sig]
+		assert.True(t, ok)
+		assert.Equal(t, promLbs1, ts.Labels)
+		assert.Len(t, ts.Samples, 1)
+		assert.Equal(t, timestamp.FromTime(now), ts.Samples[0].Timestamp)
+		assert.Equal(t, staleNaN, math.Float64bits(ts.Samples[0].Value))
+	})
+
+	t.Run("reappearing_series_is_untouched", func(t *testing.T) {
+		tracker := NewStalenessTracker(time.Minute)
+		sig := timeSeriesSignature(validMetrics1[validDoubleGauge], &promLbs1)
+
+		prev := map[uint64]*prompb.TimeSeries{
+			sig: getTimeSeries(promLbs1, getSample(floatVal1, msTime1)),
+		}
+		tracker.Track(prev)
+
+		curr := map[uint64]*prompb.TimeSeries{
+			sig: getTimeSeries(promLbs1, getSample(floatVal2, msTime2)),
+		}
+		tracker.EmitStaleMarkers(curr, now)
+
+		assert.Len(t, curr[sig].Samples, 1)
+		assert.Equal(t, getSample(floatVal2, msTime2), curr[sig].Samples[0])
+	})
+
+	t.Run("missing_series_is_marked_stale_only_once", func(t *testing.T) {
+		tracker := NewStalenessTracker(time.Minute)
+		sig := timeSeriesSignature(validMetrics1[validDoubleGauge], &promLbs1)
+
+		prev := map[uint64]*prompb.TimeSeries{
+			sig: getTimeSeries(promLbs1, getSample(floatVal1, msTime1)),
+		}
+		tracker.Track(prev)
+
+		first := map[uint64]*prompb.TimeSeries{}
+		tracker.EmitStaleMarkers(first, now)
+		assert.Len(t, first[sig].Samples, 1)
+
+		// Still missing on the next call, well within the TTL: it must not be marked stale again.
+		second := map[uint64]*prompb.TimeSeries{}
+		tracker.EmitStaleMarkers(second, now.Add(time.Second))
+		assert.Empty(t, second)
+	})
+
+	t.Run("evicted_after_ttl", func(t *testing.T) {
+		tracker := NewStalenessTracker(time.Minute)
+		sig := timeSeriesSignature(validMetrics1[validDoubleGauge], &promLbs1)
+
+		prev := map[uint64]*prompb.TimeSeries{
+			sig: getTimeSeries(promLbs1, getSample(floatVal1, msTime1)),
+		}
+		tracker.Track(prev)
+
+		// Advance well past the TTL: no marker should be emitted, and the series should no longer be tracked.
+		later := now.Add(2 * time.Minute)
+		curr := map[uint64]*prompb.TimeSeries{}
+		tracker.EmitStaleMarkers(curr, later)
+		assert.Empty(t, curr)
+
+		tracker.EmitStaleMarkers(curr, later)
+		assert.Empty(t, curr)
+	})
+}
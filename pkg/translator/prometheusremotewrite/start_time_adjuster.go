@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// seriesStart is what StartTimeAdjuster remembers about a series between calls to AdjustMetrics.
+type seriesStart struct {
+	startTime   pdata.Timestamp
+	lastValue   float64
+	hasLastSeen time.Time
+}
+
+// StartTimeAdjuster learns the first-observed timestamp for each series (keyed by the same signature
+// timeSeriesSignature uses) and rewrites every data point's StartTimestamp to that first-observed time, so
+// Prometheus sees consistent counter reset boundaries even when an OTLP producer leaves StartTimestamp unset or
+// lets it drift. When it detects a counter reset - either the value decreasing for a monotonic sum, or the
+// data point's own StartTimestamp jumping forward - it records a new start time and flags the point so the
+// caller can emit a synthetic zero-valued sample there, letting PromQL rate() handle the reset correctly.
+// Evict drops series that have not been observed within ttl; AdjustMetrics itself never evicts.
+type StartTimeAdjuster struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	state map[uint64]seriesStart
+}
+
+// NewStartTimeAdjuster returns a StartTimeAdjuster whose Evict forgets a series once ttl has elapsed since it was
+// last adjusted.
+func NewStartTimeAdjuster(ttl time.Duration) *StartTimeAdjuster {
+	return &StartTimeAdjuster{
+		ttl:   ttl,
+		state: make(map[uint64]seriesStart),
+	}
+}
+
+// AdjustMetrics rewrites the StartTimestamp of every monotonic sum, non-monotonic sum and histogram count data
+// point in md to the first-observed start time for its series, and reports the signatures that had a reset
+// detected during this call so the caller can emit a synthetic zero-valued sample at the new start time.
+func (a *StartTimeAdjuster) AdjustMetrics(md pdata.Metrics) []uint64 {
+	var resets []uint64
+	now := time.Now()
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				resets = append(resets, a.adjustMetric(metrics.At(k), now)...)
+			}
+		}
+	}
+
+	return resets
+}
+
+func (a *StartTimeAdjuster) adjustMetric(metric pdata.Metric, now time.Time) []uint64 {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeIntSum:
+		dps := metric.IntSum().DataPoints()
+		monotonic := metric.IntSum().IsMonotonic()
+		var resets []uint64
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			sig := a.pointSignature(metric, dp.LabelsMap())
+			value := float64(dp.Value())
+			if a.adjust(sig, dp.StartTimestamp(), dp.Timestamp(), value, monotonic, now) {
+				resets = append(resets, sig)
+			}
+			dp.SetStartTimestamp(a.startTimeFor(sig))
+		}
+		return resets
+	case pdata.MetricDataTypeDoubleSum:
+		dps := metric.DoubleSum().DataPoints()
+		monotonic := metric.DoubleSum().IsMonotonic()
+		var resets []uint64
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			sig := a.pointSignature(metric, dp.LabelsMap())
+			if a.adjust(sig, dp.StartTimestamp(), dp.Timestamp(), dp.Value(), monotonic, now) {
+				resets = append(resets, sig)
+			}
+			dp.SetStartTimestamp(a.startTimeFor(sig))
+		}
+		return resets
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		var resets []uint64
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			sig := a.pointSignature(metric, dp.LabelsMap())
+			if a.adjust(sig, dp.StartTimestamp(), dp.Timestamp(), float64(dp.Count()), true, now) {
+				resets = append(resets, sig)
+			}
+			dp.SetStartTimestamp(a.startTimeFor(sig))
+		}
+		return resets
+	}
+	return nil
+}
+
+// adjust records the current observation for sig and reports whether it detected a counter reset. A reset is
+// either the start timestamp jumping forward from what was previously recorded, or - for monotonic series - the
+// value decreasing from the last one seen.
+func (a *StartTimeAdjuster) adjust(sig uint64, start, ts pdata.Timestamp, value float64, monotonic bool, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prev, ok := a.state[sig]
+	reset := false
+
+	switch {
+	case !ok:
+		// First time this series is observed: the given start time (if any) or the data point's own timestamp
+		// becomes the series' start.
+		if start == 0 {
+			start = ts
+		}
+	case start > prev.startTime:
+		// The producer itself reported a new, later start time: honor it as a reset boundary.
+		reset = true
+	case monotonic && value < prev.lastValue:
+		// The counter went backwards without a new reported start time: treat the current point as the reset
+		// boundary.
+		reset = true
+		start = ts
+	default:
+		start = prev.startTime
+	}
+
+	a.state[sig] = seriesStart{startTime: start, lastValue: value, hasLastSeen: now}
+	return reset
+}
+
+func (a *StartTimeAdjuster) startTimeFor(sig uint64) pdata.Timestamp {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state[sig].startTime
+}
+
+// ResetSample builds the synthetic zero-valued sample the caller should add to the series identified by sig,
+// timestamped at the new start time AdjustMetrics recorded for it, so that PromQL rate() treats the new start as
+// the counter's reset boundary instead of extrapolating across it.
+func (a *StartTimeAdjuster) ResetSample(sig uint64) prompb.Sample {
+	return prompb.Sample{Value: 0, Timestamp: timestamp.FromTime(a.startTimeFor(sig).AsTime())}
+}
+
+// pointSignature reuses timeSeriesSignature's hashing, but over the metric's raw name and the data point's own
+// attributes only: unlike the label set addSample keys tsMap by, it carries no namespace, resource or external
+// labels, so its value differs from the series' tsMap key. timeSeriesSignature itself does not hash the metric name - callers
+// are expected to fold it into the label set, the way createAttributes' extras do for addSample - so it is added
+// here as the usual "__name__" label to keep two differently-named metrics with identical data point attributes
+// from colliding.
+func (a *StartTimeAdjuster) pointSignature(metric pdata.Metric, labels pdata.StringMap) uint64 {
+	promLabels := []prompb.Label{{Name: nameLabel, Value: metric.Name()}}
+	labels.Range(func(k, v string) bool {
+		promLabels = append(promLabels, prompb.Label{Name: k, Value: v})
+		return true
+	})
+	return timeSeriesSignature(metric, &promLabels)
+}
+
+// Evict removes every tracked series that AdjustMetrics last adjusted more than ttl before now.
+func (a *StartTimeAdjuster) Evict(now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for sig, s := range a.state {
+		if now.Sub(s.hasLastSeen) > a.ttl {
+			delete(a.state, sig)
+		}
+	}
+}
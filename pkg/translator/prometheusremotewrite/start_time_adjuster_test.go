@@ -0,0 +1,192 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newIntSumMetrics(name string, monotonic bool, start, ts pdata.Timestamp, value int64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeIntSum)
+	metric.IntSum().SetIsMonotonic(monotonic)
+	dp := metric.IntSum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetValue(value)
+	return md
+}
+
+func newDoubleSumMetrics(name string, monotonic bool, start, ts pdata.Timestamp, value float64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeDoubleSum)
+	metric.DoubleSum().SetIsMonotonic(monotonic)
+	dp := metric.DoubleSum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetValue(value)
+	return md
+}
+
+func newHistogramMetrics(name string, start, ts pdata.Timestamp, count uint64) pdata.Metrics {
+	md := pdata.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pdata.MetricDataTypeHistogram)
+	dp := metric.Histogram().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetCount(count)
+	return md
+}
+
+func firstIntSumStart(md pdata.Metrics) pdata.Timestamp {
+	return md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).IntSum().DataPoints().At(0).StartTimestamp()
+}
+
+func firstDoubleSumStart(md pdata.Metrics) pdata.Timestamp {
+	return md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).DoubleSum().DataPoints().At(0).StartTimestamp()
+}
+
+func firstHistogramStart(md pdata.Metrics) pdata.Timestamp {
+	return md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0).StartTimestamp()
+}
+
+// Test_StartTimeAdjuster_MissingStartTime checks that a data point with no StartTimestamp gets its own timestamp
+// recorded as the series start, and that a later point in the same series is rewritten to that first start time.
+func Test_StartTimeAdjuster_MissingStartTime(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	first := newIntSumMetrics("requests_total", true, 0, 1000, 1)
+	resets := adjuster.AdjustMetrics(first)
+	assert.Empty(t, resets)
+	assert.Equal(t, pdata.Timestamp(1000), firstIntSumStart(first))
+
+	second := newIntSumMetrics("requests_total", true, 0, 2000, 2)
+	resets = adjuster.AdjustMetrics(second)
+	assert.Empty(t, resets)
+	assert.Equal(t, pdata.Timestamp(1000), firstIntSumStart(second))
+}
+
+// Test_StartTimeAdjuster_IntSumReset checks that a decreasing value on a monotonic int sum is detected as a
+// counter reset and re-anchors the series' start time to the point where the reset was observed.
+func Test_StartTimeAdjuster_IntSumReset(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newIntSumMetrics("requests_total", true, 0, 1000, 10))
+
+	resetBatch := newIntSumMetrics("requests_total", true, 0, 2000, 3)
+	resets := adjuster.AdjustMetrics(resetBatch)
+	assert.Len(t, resets, 1)
+	assert.Equal(t, pdata.Timestamp(2000), firstIntSumStart(resetBatch))
+}
+
+// Test_StartTimeAdjuster_DoubleSumReset mirrors Test_StartTimeAdjuster_IntSumReset for double sums.
+func Test_StartTimeAdjuster_DoubleSumReset(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newDoubleSumMetrics("bytes_total", true, 0, 1000, 10.5))
+
+	resetBatch := newDoubleSumMetrics("bytes_total", true, 0, 2000, 1.0)
+	resets := adjuster.AdjustMetrics(resetBatch)
+	assert.Len(t, resets, 1)
+	assert.Equal(t, pdata.Timestamp(2000), firstDoubleSumStart(resetBatch))
+}
+
+// Test_StartTimeAdjuster_NonMonotonicSumNoReset checks that a decreasing value on a non-monotonic sum is not
+// treated as a reset, since non-monotonic sums are allowed to go down.
+func Test_StartTimeAdjuster_NonMonotonicSumNoReset(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newDoubleSumMetrics("queue_size", false, 0, 1000, 10))
+	resets := adjuster.AdjustMetrics(newDoubleSumMetrics("queue_size", false, 0, 2000, 1))
+	assert.Empty(t, resets)
+}
+
+// Test_StartTimeAdjuster_HistogramCountReset checks that a decreasing cumulative count is detected as a reset
+// for histograms, the same way it is for monotonic sums.
+func Test_StartTimeAdjuster_HistogramCountReset(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newHistogramMetrics("latency", 0, 1000, 100))
+
+	resetBatch := newHistogramMetrics("latency", 0, 2000, 5)
+	resets := adjuster.AdjustMetrics(resetBatch)
+	assert.Len(t, resets, 1)
+	assert.Equal(t, pdata.Timestamp(2000), firstHistogramStart(resetBatch))
+}
+
+// Test_StartTimeAdjuster_DistinctMetricNamesDoNotCollide checks that two differently-named monotonic sums with the
+// same (empty) data point attributes are tracked as separate series, rather than sharing one signature.
+func Test_StartTimeAdjuster_DistinctMetricNamesDoNotCollide(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newIntSumMetrics("requests_total", true, 0, 1000, 10))
+	adjuster.AdjustMetrics(newIntSumMetrics("errors_total", true, 0, 1000, 100))
+
+	// A drop for "errors_total" is a genuine reset for that series; it must not be masked, or conflated with, the
+	// unrelated "requests_total" series' state.
+	resetBatch := newIntSumMetrics("errors_total", true, 0, 2000, 5)
+	resets := adjuster.AdjustMetrics(resetBatch)
+	assert.Len(t, resets, 1)
+	assert.Equal(t, pdata.Timestamp(2000), firstIntSumStart(resetBatch))
+
+	// "requests_total" is unaffected by "errors_total"'s reset.
+	unaffected := newIntSumMetrics("requests_total", true, 0, 3000, 20)
+	resets = adjuster.AdjustMetrics(unaffected)
+	assert.Empty(t, resets)
+	assert.Equal(t, pdata.Timestamp(1000), firstIntSumStart(unaffected))
+}
+
+// Test_StartTimeAdjuster_ResetSample checks that, after a detected reset, ResetSample returns a zero-valued sample
+// timestamped at the series' new start time, for the caller to add to the output alongside the real samples.
+func Test_StartTimeAdjuster_ResetSample(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Hour)
+
+	adjuster.AdjustMetrics(newIntSumMetrics("requests_total", true, 0, 1000, 10))
+
+	resetBatch := newIntSumMetrics("requests_total", true, 0, 2000, 3)
+	resets := adjuster.AdjustMetrics(resetBatch)
+	assert.Len(t, resets, 1)
+
+	sample := adjuster.ResetSample(resets[0])
+	assert.Equal(t, 0.0, sample.Value)
+	assert.Equal(t, timestamp.FromTime(pdata.Timestamp(2000).AsTime()), sample.Timestamp)
+}
+
+// Test_StartTimeAdjuster_Evict checks that a series is forgotten once it has not been seen within the TTL, so a
+// later reappearance is treated as a brand-new series rather than a reset.
+func Test_StartTimeAdjuster_Evict(t *testing.T) {
+	adjuster := NewStartTimeAdjuster(time.Millisecond)
+
+	adjuster.AdjustMetrics(newIntSumMetrics("requests_total", true, 0, 1000, 10))
+	adjuster.Evict(time.Now().Add(time.Hour))
+
+	// After eviction the series is observed afresh: its own timestamp becomes the new start, and no reset is
+	// reported even though the value is lower than before.
+	fresh := newIntSumMetrics("requests_total", true, 0, 5000, 1)
+	resets := adjuster.AdjustMetrics(fresh)
+	assert.Empty(t, resets)
+	assert.Equal(t, pdata.Timestamp(5000), firstIntSumStart(fresh))
+}
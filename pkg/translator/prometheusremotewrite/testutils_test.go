@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheusremotewrite
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// Label name/value fixtures shared across the tests in this package.
+const (
+	label11 = "Label11"
+	value11 = "Value11"
+	label12 = "Label12"
+	value12 = "Value12"
+	label21 = "Label21"
+	value21 = "Value21"
+	label22 = "Label22"
+	value22 = "Value22"
+	label31 = "Label31"
+	value31 = "Value31"
+	label32 = "Label32"
+	value32 = "Value32"
+	label41 = "Label41"
+	value41 = "Value41"
+
+	dirty1 = "%"
+
+	ns1 = "test_ns"
+
+	floatVal1 = float64(1.0)
+	floatVal2 = float64(2.0)
+	intVal1   = int64(1)
+
+	msTime1 = int64(1605534761000)
+	msTime2 = int64(1605534765000)
+
+	traceIDValue1 = "traceID1"
+
+	validDoubleGauge   = "valid_double_gauge"
+	validIntGauge      = "valid_int_gauge"
+	validIntSum        = "valid_int_sum"
+	validHistogram     = "valid_histogram"
+	validIntGaugeDirty = "~" + validIntGauge + "~"
+	empty              = "empty"
+
+	// lb1Sig and lb2Sig identify the two distinct series used by the addExemplars fixtures below. They are
+	// plain sentinel values rather than real timeSeriesSignature output: addExemplars trusts the signature
+	// already recorded on a bucketBoundsData, it never recomputes one.
+	lb1Sig uint64 = 1
+	lb2Sig uint64 = 2
+)
+
+var (
+	lbs1      = getAttributeMap(map[string]string{label11: value11, label12: value12})
+	lbs1Dirty = getAttributeMap(map[string]string{label11 + dirty1: value11, "_" + label12: value12})
+
+	exlbs1 = map[string]string{label41: value41}
+	exlbs2 = map[string]string{label31: "external_" + value31, label32: "external_" + value32}
+
+	promLbs1 = getPromLabels(label11, value11, label12, value12)
+	promLbs2 = getPromLabels(label21, value21, label22, value22)
+
+	validMetrics1 = map[string]pdata.Metric{
+		validDoubleGauge: getMetric(validDoubleGauge, pdata.MetricDataTypeDoubleGauge),
+		validIntGauge:    getMetric(validIntGauge, pdata.MetricDataTypeIntGauge),
+		validIntSum:      getMetric(validIntSum, pdata.MetricDataTypeIntSum),
+		validHistogram:   getMetric(validHistogram, pdata.MetricDataTypeHistogram),
+	}
+
+	validMetrics2 = map[string]pdata.Metric{
+		validIntGaugeDirty: getMetric(validIntGaugeDirty, pdata.MetricDataTypeIntGauge),
+	}
+
+	invalidMetrics = map[string]pdata.Metric{
+		empty: getMetric("", pdata.MetricDataTypeNone),
+	}
+
+	twoPointsSameTs = map[uint64]*prompb.TimeSeries{
+		timeSeriesSignature(validMetrics1[validDoubleGauge], &promLbs1): getTimeSeries(promLbs1,
+			getSample(floatVal1, msTime1),
+			getSample(floatVal2, msTime2)),
+	}
+
+	twoPointsDifferentTs = map[uint64]*prompb.TimeSeries{
+		timeSeriesSignature(validMetrics1[validIntGauge], &promLbs1): getTimeSeries(promLbs1,
+			getSample(float64(intVal1), msTime1)),
+		timeSeriesSignature(validMetrics1[validIntGauge], &promLbs2): getTimeSeries(promLbs2,
+			getSample(float64(intVal1), msTime2)),
+	}
+
+	// tsWithoutSampleAndExemplar represents a tsMap that holds some unrelated series (keyed by lb2Sig) but not
+	// the one referenced by the bucket bounds under test (lb1Sig), so addExemplars must leave it untouched.
+	tsWithoutSampleAndExemplar = map[uint64]*prompb.TimeSeries{
+		lb2Sig: getTimeSeries(promLbs2),
+	}
+
+	tsWithSamplesAndExemplars = map[uint64]*prompb.TimeSeries{
+		lb1Sig: {
+			Labels:    promLbs1,
+			Samples:   []prompb.Sample{getSample(float64(intVal1), msTime1)},
+			Exemplars: []prompb.Exemplar{getExemplar(floatVal2, msTime1)},
+		},
+	}
+
+	tsWithInfiniteBoundExemplarValue = map[uint64]*prompb.TimeSeries{
+		lb1Sig: {
+			Labels:    promLbs1,
+			Samples:   []prompb.Sample{getSample(float64(intVal1), msTime1)},
+			Exemplars: []prompb.Exemplar{getExemplar(math.MaxFloat64, msTime1)},
+		},
+	}
+)
+
+func getAttributeMap(m map[string]string) pdata.AttributeMap {
+	am := pdata.NewAttributeMap()
+	for k, v := range m {
+		am.InsertString(k, v)
+	}
+	return am
+}
+
+func getResource(attrs map[string]pdata.AttributeValue) pdata.Resource {
+	resource := pdata.NewResource()
+	for k, v := range attrs {
+		resource.Attributes().Insert(k, v)
+	}
+	return resource
+}
+
+func getMetric(name string, dataType pdata.MetricDataType) pdata.Metric {
+	metric := pdata.NewMetric()
+	metric.SetName(name)
+	metric.SetDataType(dataType)
+
+	switch dataType {
+	case pdata.MetricDataTypeIntGauge:
+		metric.IntGauge().DataPoints().AppendEmpty()
+	case pdata.MetricDataTypeDoubleGauge:
+		metric.DoubleGauge().DataPoints().AppendEmpty()
+	case pdata.MetricDataTypeIntSum:
+		metric.IntSum().DataPoints().AppendEmpty()
+	case pdata.MetricDataTypeDoubleSum:
+		metric.DoubleSum().SetAggregationTemporality(pdata.MetricAggregationTemporalityCumulative)
+		metric.DoubleSum().DataPoints().AppendEmpty()
+	case pdata.MetricDataTypeHistogram:
+		metric.Histogram().SetAggregationTemporality(pdata.MetricAggregationTemporalityCumulative)
+		metric.Histogram().DataPoints().AppendEmpty()
+	case pdata.MetricDataTypeSummary:
+		metric.Summary().DataPoints().AppendEmpty()
+	}
+
+	return metric
+}
+
+func getPromLabels(kv ...string) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(kv)/2)
+	for i := 0; i < len(kv)-1; i += 2 {
+		labels = append(labels, getLabel(kv[i], kv[i+1]))
+	}
+	return labels
+}
+
+func getLabel(name, value string) prompb.Label {
+	return prompb.Label{Name: name, Value: value}
+}
+
+func getSample(value float64, ts int64) prompb.Sample {
+	return prompb.Sample{Value: value, Timestamp: ts}
+}
+
+func getTimeSeries(labels []prompb.Label, samples ...prompb.Sample) *prompb.TimeSeries {
+	return &prompb.TimeSeries{Labels: labels, Samples: samples}
+}
+
+func getExemplar(value float64, ts int64) prompb.Exemplar {
+	return prompb.Exemplar{Value: value, Timestamp: ts}
+}
+
+func getBucketBoundsData(bounds []float64) []bucketBoundsData {
+	data := make([]bucketBoundsData, len(bounds))
+	for i, b := range bounds {
+		data[i] = bucketBoundsData{sig: lb1Sig, bound: b}
+	}
+	return data
+}
+
+func getHistogramDataPoint() *pdata.HistogramDataPoint {
+	hp := pdata.NewHistogramDataPoint()
+	return &hp
+}
+
+func getHistogramDataPointWithExemplars(t time.Time, value float64, attrKey, attrValue string) *pdata.HistogramDataPoint {
+	hp := pdata.NewHistogramDataPoint()
+	exemplar := hp.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(pdata.NewTimestampFromTime(t))
+	exemplar.SetDoubleVal(value)
+	exemplar.FilteredAttributes().InsertString(attrKey, attrValue)
+	return &hp
+}
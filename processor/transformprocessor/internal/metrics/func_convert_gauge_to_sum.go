@@ -0,0 +1,62 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor/internal/metrics"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/telemetryquerylanguage/tql"
+)
+
+func convertGaugeToSum(temporality string, monotonic bool) (tql.ExprFunc, error) {
+	var aggTemp pmetric.MetricAggregationTemporality
+	switch temporality {
+	case "cumulative":
+		aggTemp = pmetric.MetricAggregationTemporalityCumulative
+	case "delta":
+		aggTemp = pmetric.MetricAggregationTemporalityDelta
+	default:
+		return nil, fmt.Errorf("unknown temporality %s, allowed values are \"delta\" and \"cumulative\"", temporality)
+	}
+
+	return func(ctx tql.TransformContext) interface{} {
+		mtc, ok := ctx.(metricTransformContext)
+		if !ok {
+			return nil
+		}
+
+		metric := mtc.GetMetric()
+		if metric.DataType() != pmetric.MetricDataTypeGauge {
+			return nil
+		}
+
+		gaugeToSum(metric, aggTemp, monotonic)
+
+		return nil
+	}, nil
+}
+
+// gaugeToSum converts metric in place from a gauge to a sum, carrying over its data points unchanged.
+func gaugeToSum(metric pmetric.Metric, aggTemp pmetric.MetricAggregationTemporality, monotonic bool) {
+	dps := metric.Gauge().DataPoints()
+
+	metric.SetDataType(pmetric.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(aggTemp)
+	metric.Sum().SetIsMonotonic(monotonic)
+	// Setting the data type removed all the data points, so we must copy them back to the metric.
+	dps.CopyTo(metric.Sum().DataPoints())
+}
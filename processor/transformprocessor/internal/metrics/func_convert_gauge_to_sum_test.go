@@ -0,0 +1,76 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newGaugeMetric(values ...float64) pmetric.Metric {
+	metric := pmetric.NewMetric()
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	for _, v := range values {
+		metric.Gauge().DataPoints().AppendEmpty().SetDoubleVal(v)
+	}
+	return metric
+}
+
+func Test_convertGaugeToSum_invalidTemporality(t *testing.T) {
+	_, err := convertGaugeToSum("unknown", true)
+	assert.Error(t, err)
+}
+
+func Test_convertGaugeToSum_validTemporalities(t *testing.T) {
+	for _, temporality := range []string{"cumulative", "delta"} {
+		for _, monotonic := range []bool{true, false} {
+			_, err := convertGaugeToSum(temporality, monotonic)
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func Test_gaugeToSum_copiesDataPoints(t *testing.T) {
+	metric := newGaugeMetric(1.5, 2.5, 3.5)
+
+	gaugeToSum(metric, pmetric.MetricAggregationTemporalityCumulative, true)
+
+	assert.Equal(t, pmetric.MetricDataTypeSum, metric.DataType())
+	assert.Equal(t, pmetric.MetricAggregationTemporalityCumulative, metric.Sum().AggregationTemporality())
+	assert.True(t, metric.Sum().IsMonotonic())
+	assert.Equal(t, 3, metric.Sum().DataPoints().Len())
+	assert.Equal(t, 1.5, metric.Sum().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, 2.5, metric.Sum().DataPoints().At(1).DoubleVal())
+	assert.Equal(t, 3.5, metric.Sum().DataPoints().At(2).DoubleVal())
+}
+
+func Test_gaugeToSum_nonMonotonicDelta(t *testing.T) {
+	metric := newGaugeMetric(42)
+
+	gaugeToSum(metric, pmetric.MetricAggregationTemporalityDelta, false)
+
+	assert.Equal(t, pmetric.MetricAggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+	assert.False(t, metric.Sum().IsMonotonic())
+}
+
+func Test_gaugeToSum_emptyDataPoints(t *testing.T) {
+	metric := newGaugeMetric()
+
+	gaugeToSum(metric, pmetric.MetricAggregationTemporalityCumulative, true)
+
+	assert.Equal(t, 0, metric.Sum().DataPoints().Len())
+}
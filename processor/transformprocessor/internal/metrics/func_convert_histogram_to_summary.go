@@ -0,0 +1,123 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor/internal/metrics"
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/telemetryquerylanguage/tql"
+)
+
+// convertHistogramToSummary computes the requested quantiles from a histogram's cumulative bucket counts via
+// linear interpolation between the lower and upper boundaries of the bucket each quantile falls in. Like convertSummaryToHistogram's reverse direction,
+// this is inherently an approximation: a histogram's buckets only bound where an observation falls, not its
+// exact value.
+func convertHistogramToSummary(quantiles []float64) (tql.ExprFunc, error) {
+	sortedQuantiles := make([]float64, len(quantiles))
+	copy(sortedQuantiles, quantiles)
+	sort.Float64s(sortedQuantiles)
+
+	return func(ctx tql.TransformContext) interface{} {
+		mtc, ok := ctx.(metricTransformContext)
+		if !ok {
+			return nil
+		}
+
+		histogramToSummary(mtc.GetMetric(), sortedQuantiles)
+
+		return nil
+	}, nil
+}
+
+// histogramToSummary converts metric in place from a histogram to a summary reporting the given (already sorted)
+// quantiles, estimating each quantile's value from the histogram's bucket counts. Metrics that aren't a Histogram
+// are left untouched.
+func histogramToSummary(metric pmetric.Metric, quantiles []float64) {
+	if metric.DataType() != pmetric.MetricDataTypeHistogram {
+		return
+	}
+
+	histogramPoints := metric.Histogram().DataPoints()
+	summaryPoints := make([]pmetric.SummaryDataPoint, histogramPoints.Len())
+	for i := 0; i < histogramPoints.Len(); i++ {
+		summaryPoints[i] = histogramPointToSummaryPoint(histogramPoints.At(i), quantiles)
+	}
+
+	metric.SetDataType(pmetric.MetricDataTypeSummary)
+	dps := metric.Summary().DataPoints()
+	for _, sp := range summaryPoints {
+		sp.CopyTo(dps.AppendEmpty())
+	}
+}
+
+func histogramPointToSummaryPoint(hp pmetric.HistogramDataPoint, quantiles []float64) pmetric.SummaryDataPoint {
+	sp := pmetric.NewSummaryDataPoint()
+	sp.SetStartTimestamp(hp.StartTimestamp())
+	sp.SetTimestamp(hp.Timestamp())
+	sp.SetCount(hp.Count())
+	sp.SetSum(hp.Sum())
+
+	bounds := hp.ExplicitBounds()
+	counts := hp.BucketCounts()
+
+	qv := sp.QuantileValues()
+	for _, q := range quantiles {
+		qv.AppendEmpty().SetQuantile(q)
+	}
+	for i, q := range quantiles {
+		qv.At(i).SetValue(valueAtQuantile(bounds, counts, hp.Count(), q))
+	}
+
+	return sp
+}
+
+// valueAtQuantile walks the cumulative bucket counts until it finds the bucket where the target quantile falls,
+// then linearly interpolates between that bucket's boundaries to estimate the value at q. The first bucket's lower
+// bound is taken as 0; for the +Inf bucket, which has no upper bound, its lower bound is returned as the estimate.
+func valueAtQuantile(bounds []float64, counts []uint64, total uint64, q float64) float64 {
+	if total == 0 || len(counts) == 0 {
+		return 0
+	}
+
+	target := q * float64(total)
+	var cumulative uint64
+	for i, count := range counts {
+		prevCumulative := cumulative
+		cumulative += count
+		if float64(cumulative) < target && i != len(counts)-1 {
+			continue
+		}
+
+		lowerBound := 0.0
+		if i > 0 {
+			lowerBound = bounds[i-1]
+		}
+		if i == len(counts)-1 && (len(bounds) == 0 || i >= len(bounds)) {
+			// The +Inf bucket has no upper bound; report its lower bound rather than extrapolating past it.
+			return lowerBound
+		}
+		upperBound := bounds[i]
+
+		if count == 0 {
+			return lowerBound
+		}
+		frac := (target - float64(prevCumulative)) / float64(count)
+		return lowerBound + frac*(upperBound-lowerBound)
+	}
+
+	return bounds[len(bounds)-1]
+}
@@ -0,0 +1,90 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newHistogramDataPoint(count uint64, sum float64, bounds []float64, counts []uint64) pmetric.HistogramDataPoint {
+	hp := pmetric.NewHistogramDataPoint()
+	hp.SetCount(count)
+	hp.SetSum(sum)
+	hp.SetExplicitBounds(bounds)
+	hp.SetBucketCounts(counts)
+	return hp
+}
+
+func Test_histogramPointToSummaryPoint(t *testing.T) {
+	hp := newHistogramDataPoint(100, 1000, []float64{10, 20}, []uint64{50, 40, 10})
+
+	sp := histogramPointToSummaryPoint(hp, []float64{0.5, 0.99})
+
+	assert.Equal(t, uint64(100), sp.Count())
+	assert.Equal(t, 1000.0, sp.Sum())
+	assert.Equal(t, 2, sp.QuantileValues().Len())
+	assert.Equal(t, 0.5, sp.QuantileValues().At(0).Quantile())
+	assert.Equal(t, 0.99, sp.QuantileValues().At(1).Quantile())
+	// p50 falls exactly at the first bucket's upper bound (50 of 100 observations are <= 10).
+	assert.InDelta(t, 10, sp.QuantileValues().At(0).Value(), 1e-9)
+	// p99 falls in the +Inf bucket, so the estimate reports that bucket's lower bound.
+	assert.InDelta(t, 20, sp.QuantileValues().At(1).Value(), 1e-9)
+}
+
+func Test_histogramToSummary_wrongDataType(t *testing.T) {
+	metric := pmetric.NewMetric()
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	metric.Gauge().DataPoints().AppendEmpty()
+
+	histogramToSummary(metric, []float64{0.5, 0.99})
+
+	assert.Equal(t, pmetric.MetricDataTypeGauge, metric.DataType())
+	assert.Equal(t, 1, metric.Gauge().DataPoints().Len())
+}
+
+func Test_histogramToSummary_convertsMultiplePoints(t *testing.T) {
+	metric := pmetric.NewMetric()
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	newHistogramDataPoint(100, 1000, []float64{10, 20}, []uint64{50, 40, 10}).CopyTo(metric.Histogram().DataPoints().AppendEmpty())
+	newHistogramDataPoint(50, 200, []float64{10, 20}, []uint64{20, 20, 10}).CopyTo(metric.Histogram().DataPoints().AppendEmpty())
+
+	histogramToSummary(metric, []float64{0.5, 0.99})
+
+	assert.Equal(t, pmetric.MetricDataTypeSummary, metric.DataType())
+	assert.Equal(t, 2, metric.Summary().DataPoints().Len())
+	assert.Equal(t, uint64(100), metric.Summary().DataPoints().At(0).Count())
+	assert.Equal(t, uint64(50), metric.Summary().DataPoints().At(1).Count())
+	assert.Equal(t, 2, metric.Summary().DataPoints().At(0).QuantileValues().Len())
+}
+
+func Test_valueAtQuantile_firstBucket(t *testing.T) {
+	// All observations fall in the first, zero-lower-bound bucket.
+	v := valueAtQuantile([]float64{10, 20}, []uint64{100, 0, 0}, 100, 0.5)
+	assert.InDelta(t, 5, v, 1e-9)
+}
+
+func Test_valueAtQuantile_infBucket(t *testing.T) {
+	// The requested quantile falls past the last explicit bound, in the +Inf bucket.
+	v := valueAtQuantile([]float64{10, 20}, []uint64{10, 10, 80}, 100, 0.95)
+	assert.Equal(t, 20.0, v)
+}
+
+func Test_valueAtQuantile_emptyHistogram(t *testing.T) {
+	v := valueAtQuantile(nil, nil, 0, 0.5)
+	assert.Equal(t, 0.0, v)
+}
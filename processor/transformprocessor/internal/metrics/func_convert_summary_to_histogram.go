@@ -0,0 +1,123 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/transformprocessor/internal/metrics"
+
+import (
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/telemetryquerylanguage/tql"
+)
+
+// convertSummaryToHistogram reconstructs histogram buckets for bounds from a summary's quantile data points. Since
+// a summary only records a handful of quantiles, a bucket's count is necessarily an estimate: it is obtained by
+// linearly interpolating, between the two quantiles whose values straddle the bucket bound, what fraction of the
+// total count falls at or below that bound.
+func convertSummaryToHistogram(bounds []float64) (tql.ExprFunc, error) {
+	sortedBounds := make([]float64, len(bounds))
+	copy(sortedBounds, bounds)
+	sort.Float64s(sortedBounds)
+
+	return func(ctx tql.TransformContext) interface{} {
+		mtc, ok := ctx.(metricTransformContext)
+		if !ok {
+			return nil
+		}
+
+		summaryToHistogram(mtc.GetMetric(), sortedBounds)
+
+		return nil
+	}, nil
+}
+
+// summaryToHistogram converts metric in place from a summary to a cumulative histogram with the given (already
+// sorted) bounds, estimating each bucket's count from the summary's quantiles. Metrics that aren't a Summary are
+// left untouched.
+func summaryToHistogram(metric pmetric.Metric, bounds []float64) {
+	if metric.DataType() != pmetric.MetricDataTypeSummary {
+		return
+	}
+
+	summaryPoints := metric.Summary().DataPoints()
+	histogramPoints := make([]pmetric.HistogramDataPoint, summaryPoints.Len())
+	for i := 0; i < summaryPoints.Len(); i++ {
+		histogramPoints[i] = summaryPointToHistogramPoint(summaryPoints.At(i), bounds)
+	}
+
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	metric.Histogram().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+	dps := metric.Histogram().DataPoints()
+	for _, hp := range histogramPoints {
+		hp.CopyTo(dps.AppendEmpty())
+	}
+}
+
+func summaryPointToHistogramPoint(sp pmetric.SummaryDataPoint, bounds []float64) pmetric.HistogramDataPoint {
+	hp := pmetric.NewHistogramDataPoint()
+	hp.SetStartTimestamp(sp.StartTimestamp())
+	hp.SetTimestamp(sp.Timestamp())
+	hp.SetCount(sp.Count())
+	hp.SetSum(sp.Sum())
+	hp.SetExplicitBounds(bounds)
+
+	quantiles := sp.QuantileValues()
+	counts := make([]uint64, len(bounds)+1)
+	prevCumulative := uint64(0)
+	for i, bound := range bounds {
+		fraction := interpolateCumulativeFraction(quantiles, bound)
+		cumulative := uint64(fraction * float64(sp.Count()))
+		if cumulative < prevCumulative {
+			cumulative = prevCumulative
+		}
+		counts[i] = cumulative - prevCumulative
+		prevCumulative = cumulative
+	}
+	// The +Inf bucket picks up everything the explicit bounds didn't already account for.
+	counts[len(bounds)] = sp.Count() - prevCumulative
+	hp.SetBucketCounts(counts)
+
+	return hp
+}
+
+// interpolateCumulativeFraction estimates the fraction of observations at or below bound, given a sorted-by-value
+// set of (quantile, value) pairs. Below the lowest recorded quantile the fraction is extrapolated down to zero at
+// value zero; above the highest it is extrapolated up to 1.
+func interpolateCumulativeFraction(quantiles pmetric.ValueAtQuantileSlice, bound float64) float64 {
+	if quantiles.Len() == 0 {
+		return 0
+	}
+
+	first := quantiles.At(0)
+	if bound <= first.Value() {
+		if first.Value() == 0 {
+			return first.Quantile()
+		}
+		return first.Quantile() * (bound / first.Value())
+	}
+
+	for i := 1; i < quantiles.Len(); i++ {
+		lo, hi := quantiles.At(i-1), quantiles.At(i)
+		if bound <= hi.Value() {
+			if hi.Value() == lo.Value() {
+				return hi.Quantile()
+			}
+			frac := (bound - lo.Value()) / (hi.Value() - lo.Value())
+			return lo.Quantile() + frac*(hi.Quantile()-lo.Quantile())
+		}
+	}
+
+	return 1
+}
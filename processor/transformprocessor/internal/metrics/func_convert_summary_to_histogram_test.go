@@ -0,0 +1,98 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newSummaryDataPoint(count uint64, sum float64, qv ...float64) pmetric.SummaryDataPoint {
+	sp := pmetric.NewSummaryDataPoint()
+	sp.SetCount(count)
+	sp.SetSum(sum)
+	for i := 0; i < len(qv)-1; i += 2 {
+		q := sp.QuantileValues().AppendEmpty()
+		q.SetQuantile(qv[i])
+		q.SetValue(qv[i+1])
+	}
+	return sp
+}
+
+func Test_summaryPointToHistogramPoint_emptyQuantiles(t *testing.T) {
+	sp := newSummaryDataPoint(100, 500)
+	hp := summaryPointToHistogramPoint(sp, []float64{1, 5, 10})
+
+	assert.Equal(t, uint64(100), hp.Count())
+	assert.Equal(t, 500.0, hp.Sum())
+	total := uint64(0)
+	for _, c := range hp.BucketCounts() {
+		total += c
+	}
+	assert.Equal(t, sp.Count(), total)
+}
+
+func Test_summaryPointToHistogramPoint_interpolates(t *testing.T) {
+	// p50=5, p99=20: a bound of 5 should land right at the 50th percentile.
+	sp := newSummaryDataPoint(1000, 7500, 0.5, 5, 0.99, 20)
+	hp := summaryPointToHistogramPoint(sp, []float64{5, 20})
+
+	total := uint64(0)
+	for _, c := range hp.BucketCounts() {
+		total += c
+	}
+	assert.Equal(t, sp.Count(), total)
+	assert.Len(t, hp.BucketCounts(), 3) // two explicit bounds plus the +Inf bucket
+	assert.InDelta(t, 500, hp.BucketCounts()[0], 1)
+}
+
+func Test_summaryToHistogram_wrongDataType(t *testing.T) {
+	metric := pmetric.NewMetric()
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	metric.Gauge().DataPoints().AppendEmpty()
+
+	summaryToHistogram(metric, []float64{1, 5, 10})
+
+	assert.Equal(t, pmetric.MetricDataTypeGauge, metric.DataType())
+	assert.Equal(t, 1, metric.Gauge().DataPoints().Len())
+}
+
+func Test_summaryToHistogram_convertsMultiplePoints(t *testing.T) {
+	metric := pmetric.NewMetric()
+	metric.SetDataType(pmetric.MetricDataTypeSummary)
+	newSummaryDataPoint(100, 500).CopyTo(metric.Summary().DataPoints().AppendEmpty())
+	newSummaryDataPoint(1000, 7500, 0.5, 5, 0.99, 20).CopyTo(metric.Summary().DataPoints().AppendEmpty())
+
+	summaryToHistogram(metric, []float64{5, 20})
+
+	assert.Equal(t, pmetric.MetricDataTypeHistogram, metric.DataType())
+	assert.Equal(t, pmetric.MetricAggregationTemporalityCumulative, metric.Histogram().AggregationTemporality())
+	assert.Equal(t, 2, metric.Histogram().DataPoints().Len())
+	assert.Equal(t, uint64(100), metric.Histogram().DataPoints().At(0).Count())
+	assert.Equal(t, uint64(1000), metric.Histogram().DataPoints().At(1).Count())
+}
+
+func Test_interpolateCumulativeFraction_boundaries(t *testing.T) {
+	sp := newSummaryDataPoint(100, 100, 0.5, 10, 0.9, 20)
+
+	// Below the lowest recorded quantile, the fraction is extrapolated linearly down to zero.
+	assert.InDelta(t, 0.25, interpolateCumulativeFraction(sp.QuantileValues(), 5), 1e-9)
+	// Exactly at a recorded quantile's value.
+	assert.InDelta(t, 0.5, interpolateCumulativeFraction(sp.QuantileValues(), 10), 1e-9)
+	// Above the highest recorded quantile.
+	assert.InDelta(t, 1, interpolateCumulativeFraction(sp.QuantileValues(), 1000), 1e-9)
+}